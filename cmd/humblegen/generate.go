@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"text/template"
+)
+
+// generate renders the "<type>_gen.go" source for spec and gofmts it.
+func generate(spec *modelSpec) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := genTemplate.Execute(&buf, spec); err != nil {
+		return nil, err
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %s\n%s", err.Error(), buf.String())
+	}
+	return formatted, nil
+}
+
+var genTemplate = template.Must(template.New("gen").Parse(`// Code generated by humblegen. DO NOT EDIT.
+
+//go:generate humblegen -type {{.Name}} {{.SourceFile}}
+
+package {{.Package}}
+{{if or (.HasView "item") (.HasView "list") (.HasView "footer")}}
+import (
+	"github.com/gophergala/humble"
+)
+{{end}}
+func (m *{{.Name}}) GetId() string {
+	return m.{{.IdField}}
+}
+
+func (m *{{.Name}}) RootURL() string {
+	return "{{.RootURL}}"
+}
+{{if .HasView "item"}}
+// {{.Name}}View renders a single {{.Name}}.
+type {{.Name}}View struct {
+	humble.Identifier
+	Model *{{.Name}}
+}
+
+func (v *{{.Name}}View) OuterTag() string {
+	return "li"
+}
+
+func (v *{{.Name}}View) RenderHTML() string {
+	// TODO: fill in markup for {{.Name}}View.
+	return ""
+}
+
+func (v *{{.Name}}View) OnLoad() error {
+	return nil
+}
+{{end}}
+{{if .HasView "list"}}
+// {{.Name}}List renders a collection of {{.Name}}View.
+type {{.Name}}List struct {
+	humble.Identifier
+	{{.Name}}Views *[]*{{.Name}}View
+}
+
+func (v *{{.Name}}List) OuterTag() string {
+	return "ul"
+}
+
+func (v *{{.Name}}List) RenderHTML() string {
+	html := ""
+	if v.{{.Name}}Views == nil {
+		return html
+	}
+	for _, item := range *v.{{.Name}}Views {
+		html += item.RenderHTML()
+	}
+	return html
+}
+
+func (v *{{.Name}}List) OnLoad() error {
+	return nil
+}
+{{end}}
+{{if .HasView "footer"}}
+// {{.Name}}Footer summarizes a collection of {{.Name}}View, analogous to the
+// hand-written Footer in example/todomvc.
+type {{.Name}}Footer struct {
+	humble.Identifier
+	{{.Name}}Views *[]*{{.Name}}View
+}
+
+func (f *{{.Name}}Footer) OuterTag() string {
+	return "div"
+}
+
+func (f *{{.Name}}Footer) RenderHTML() string {
+	// TODO: fill in markup for {{.Name}}Footer.
+	return ""
+}
+
+func (f *{{.Name}}Footer) OnLoad() error {
+	return nil
+}
+{{range $field := .BoolFields}}
+func (f *{{$.Name}}Footer) count{{$field}}() int {
+	count := 0
+	if f.{{$.Name}}Views == nil {
+		return count
+	}
+	for _, item := range *f.{{$.Name}}Views {
+		if item.Model.{{$field}} {
+			count++
+		}
+	}
+	return count
+}
+{{end}}
+{{end}}
+`))
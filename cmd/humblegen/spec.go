@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// modelSpec describes everything humblegen needs to know about one tagged
+// struct in order to scaffold its Model and View boilerplate.
+type modelSpec struct {
+	Package    string
+	Name       string
+	IdField    string
+	RootURL    string
+	Views      []string // any of "item", "list", "footer"
+	BoolFields []string
+	// SourceFile is the base name of the file spec was parsed from, e.g.
+	// "todo.go". It is emitted as a //go:generate directive in the
+	// generated file, so that re-running `go generate ./...` after editing
+	// the struct doesn't depend on the directive also being copied into
+	// SourceFile by hand.
+	SourceFile string
+}
+
+// HasView reports whether spec was tagged to generate the given view kind.
+func (spec *modelSpec) HasView(kind string) bool {
+	for _, v := range spec.Views {
+		if v == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFile reads the Go source file at path and returns a modelSpec for
+// every struct type that carries a `humble` or `view` struct tag on at least
+// one field. If typeName is non-empty, only the struct with that name is
+// considered, and parseFile returns an error if it isn't found or isn't
+// tagged.
+func parseFile(path string, typeName string) ([]*modelSpec, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %s", path, err.Error())
+	}
+	var specs []*modelSpec
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		typeSpec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		structType, ok := typeSpec.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		if typeName != "" && typeSpec.Name.Name != typeName {
+			return true
+		}
+		found = true
+		spec := specFromStruct(file.Name.Name, typeSpec.Name.Name, structType)
+		if spec != nil {
+			spec.SourceFile = filepath.Base(path)
+			specs = append(specs, spec)
+		}
+		return true
+	})
+	if typeName != "" && !found {
+		return nil, fmt.Errorf("no struct named %s in %s", typeName, path)
+	}
+	return specs, nil
+}
+
+// specFromStruct builds a modelSpec from a struct's fields, reading the
+// `humble` and `view` tags off of whichever fields carry them. It returns
+// nil if none of the fields have either tag.
+func specFromStruct(pkg, name string, structType *ast.StructType) *modelSpec {
+	spec := &modelSpec{Package: pkg, Name: name}
+	tagged := false
+	for _, field := range structType.Fields.List {
+		fieldName := ""
+		if len(field.Names) > 0 {
+			fieldName = field.Names[0].Name
+		}
+		if isBoolField(field) && fieldName != "" {
+			spec.BoolFields = append(spec.BoolFields, fieldName)
+		}
+		if field.Tag == nil {
+			continue
+		}
+		tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+		if humbleTag, ok := tag.Lookup("humble"); ok {
+			tagged = true
+			switch {
+			case humbleTag == "id":
+				spec.IdField = fieldName
+			case strings.HasPrefix(humbleTag, "root="):
+				spec.RootURL = strings.TrimPrefix(humbleTag, "root=")
+			}
+		}
+		if viewTag, ok := tag.Lookup("view"); ok {
+			tagged = true
+			for _, kind := range strings.Split(viewTag, ",") {
+				kind = strings.TrimSpace(kind)
+				if kind != "" {
+					spec.Views = append(spec.Views, kind)
+				}
+			}
+		}
+	}
+	if !tagged {
+		return nil
+	}
+	return spec
+}
+
+// isBoolField reports whether field's type is the predeclared bool type.
+func isBoolField(field *ast.Field) bool {
+	ident, ok := field.Type.(*ast.Ident)
+	return ok && ident.Name == "bool"
+}
@@ -3,6 +3,7 @@ package views
 import (
 	"fmt"
 	"github.com/gophergala/humble"
+	"github.com/gophergala/humble/model"
 	"github.com/gophergala/humble/view"
 	"honnef.co/go/js/dom"
 	"strings"
@@ -11,6 +12,26 @@ import (
 type Footer struct {
 	humble.Identifier
 	TodoViews *[]*Todo
+
+	refreshTodoViews func()
+	unbind           func()
+}
+
+// NewFooter constructs a Footer bound to todos, so that TodoViews is rebuilt
+// from todos' current items (via toViews) every time todos changes through
+// model.Create, model.Update, or model.Delete. Previously every call site
+// that mutated a todo also had to remember to rebuild and reload whichever
+// Footer was showing its count; binding here means that now happens
+// automatically, no matter what triggered the change.
+func NewFooter[T model.Model](todos *model.Collection[T], toViews func([]T) []*Todo) *Footer {
+	f := &Footer{}
+	f.refreshTodoViews = func() {
+		views := toViews(todos.Items())
+		f.TodoViews = &views
+	}
+	f.refreshTodoViews()
+	f.unbind = view.Bind(f, todos)
+	return f
 }
 
 func (f *Footer) RenderHTML() string {
@@ -36,12 +57,24 @@ func (f *Footer) OuterTag() string {
 }
 
 func (f *Footer) OnLoad() error {
+	if f.refreshTodoViews != nil {
+		f.refreshTodoViews()
+	}
 	if err := f.setSelected(); err != nil {
 		return err
 	}
 	return nil
 }
 
+// Unbind removes f's binding to the Collection it was constructed with, if
+// any. Callers should invoke it when f is removed from the view tree so
+// that it stops being notified of changes it can no longer render.
+func (f *Footer) Unbind() {
+	if f.unbind != nil {
+		f.unbind()
+	}
+}
+
 func (f *Footer) countRemaining() int {
 	count := 0
 	if f.TodoViews == nil {
@@ -0,0 +1,358 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequestOption is a middleware function that can inspect or modify a request
+// before it is sent, e.g. to attach an Authorization header, a CSRF token, or
+// rewrite the URL to point at a different host. Returning an error aborts the
+// request.
+type RequestOption func(*http.Request) error
+
+// defaultMaxRetries is the number of times an idempotent request (GET, PUT,
+// or DELETE) is retried after a 5xx response or a network error before
+// giving up.
+const defaultMaxRetries = 3
+
+// defaultRetryBackoff is the base delay used for exponential backoff between
+// retries. It is doubled on each subsequent attempt.
+const defaultRetryBackoff = 200 * time.Millisecond
+
+// Client sends the requests built by Create, Read, ReadAll, Update, and
+// Delete. The zero value is ready to use and behaves like DefaultClient.
+type Client struct {
+	// HTTPClient does the actual round trip. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+	// UserAgent, if set, is sent as the User-Agent header on every request.
+	UserAgent string
+	// Options are applied, in order, to every outgoing request after
+	// UserAgent is set and before the request is sent.
+	Options []RequestOption
+	// MaxRetries is the number of times an idempotent request is retried
+	// after a 5xx response or a network error. If zero, defaultMaxRetries is used.
+	MaxRetries int
+}
+
+// DefaultClient is the Client used by the package-level Create, Read,
+// ReadAll, Update, and Delete functions. Browser apps that need to set auth
+// headers or a custom base client can replace it or construct their own
+// Client instead.
+var DefaultClient = &Client{}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) maxRetries() int {
+	if c.MaxRetries != 0 {
+		return c.MaxRetries
+	}
+	return defaultMaxRetries
+}
+
+// Create is identical to the package-level Create, except that it sends the
+// request with c instead of DefaultClient.
+func (c *Client) Create(model Model) error {
+	return c.sendModelRequest("POST", baseURLFor(model), model)
+}
+
+// Read is identical to the package-level Read, except that it sends the
+// request with c instead of DefaultClient.
+func (c *Client) Read(id string, model Model) error {
+	fullURL := model.RootURL() + "/" + id
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return fmt.Errorf("Something went wrong building GET request to %s: %s", fullURL, err.Error())
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(resp.Body, model); err != nil {
+		return err
+	}
+	if setter, ok := model.(ETagSetter); ok {
+		setter.SetETag(resp.Header.Get("ETag"))
+	}
+	return nil
+}
+
+// ReadAll is identical to the package-level ReadAll, except that it sends
+// the request with c instead of DefaultClient.
+func (c *Client) ReadAll(models interface{}) error {
+	rootURL, err := getURLFromModels(models)
+	if err != nil {
+		return err
+	}
+	return c.sendRequestAndUnmarshal("GET", rootURL, "", models)
+}
+
+// Update is identical to the package-level Update, except that it sends the
+// request with c instead of DefaultClient.
+func (c *Client) Update(model Model) error {
+	baseURL := model.RootURL() + "/" + model.GetId()
+	if _, ok := model.(URLTemplater); ok {
+		baseURL = baseURLFor(model)
+	}
+	return c.sendModelRequest("PUT", baseURL, model)
+}
+
+// Delete is identical to the package-level Delete, except that it sends the
+// request with c instead of DefaultClient.
+func (c *Client) Delete(model Model) error {
+	fullURL := model.RootURL() + "/" + model.GetId()
+	req, err := http.NewRequest("DELETE", fullURL, nil)
+	if err != nil {
+		return fmt.Errorf("Something went wrong building DELETE request to %s: %s", fullURL, err.Error())
+	}
+	_, err = c.do(req)
+	return err
+}
+
+// Patch is identical to the package-level Patch, except that it sends the
+// request with c instead of DefaultClient.
+func (c *Client) Patch(model Model, fields ...string) error {
+	baseURL := model.RootURL() + "/" + model.GetId()
+	if _, ok := model.(URLTemplater); ok {
+		baseURL = baseURLFor(model)
+	}
+	enc, err := encodeModelFields(model, fields...)
+	if err != nil {
+		return err
+	}
+	enc.contentType = "application/merge-patch+json"
+	return c.sendEncodedRequest("PATCH", baseURL, model, enc)
+}
+
+// sendRequestAndUnmarshal constructs a request with the given method, url,
+// and data. If data is an empty string, it will construct a request without
+// any data in the body. If data is a non-empty string, it will send it as
+// the body of the request and set the Content-Type header to
+// application/x-www-form-urlencoded.
+func (c *Client) sendRequestAndUnmarshal(method string, url string, data string, v interface{}) error {
+	req, err := http.NewRequest(method, url, strings.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("Something went wrong building %s request to %s: %s", method, url, err.Error())
+	}
+	if data != "" {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return c.doAndUnmarshal(req, v)
+}
+
+// sendModelRequest encodes model according to its struct tags (see
+// encodeModelFields), builds a request with the given method against
+// baseURL, and sends it.
+func (c *Client) sendModelRequest(method string, baseURL string, model Model) error {
+	var (
+		enc *encodedRequest
+		err error
+	)
+	if fe, ok := model.(FormEncoder); ok && fe.RequestEncoding() == formEncoding {
+		enc, err = encodeModelFieldsForm(model)
+	} else {
+		enc, err = encodeModelFields(model)
+	}
+	if err != nil {
+		return err
+	}
+	return c.sendEncodedRequest(method, baseURL, model, enc)
+}
+
+// sendEncodedRequest builds a request with the given method against baseURL
+// from the already-encoded enc, sets its headers, cookies, and (for PUT and
+// PATCH) an If-Match header if model implements ETager, sends it, and
+// unmarshals the response into model. If the server responds with 412
+// Precondition Failed to a PUT or PATCH, it returns a *ConflictError instead.
+func (c *Client) sendEncodedRequest(method string, baseURL string, model Model, enc *encodedRequest) error {
+	fullURL := enc.buildURL(baseURL)
+	var bodyReader io.Reader
+	if len(enc.body) > 0 {
+		bodyReader = bytes.NewReader(enc.body)
+	}
+	req, err := http.NewRequest(method, fullURL, bodyReader)
+	if err != nil {
+		return fmt.Errorf("Something went wrong building %s request to %s: %s", method, fullURL, err.Error())
+	}
+	if enc.contentType != "" {
+		req.Header.Set("Content-Type", enc.contentType)
+	}
+	for name, values := range enc.header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	for _, cookie := range enc.cookies {
+		req.AddCookie(cookie)
+	}
+	if etager, ok := model.(ETager); ok && (method == "PUT" || method == "PATCH") {
+		if etag := etager.ETag(); etag != "" {
+			req.Header.Set("If-Match", etag)
+		}
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		if herr, ok := err.(*HTTPError); ok && herr.StatusCode == http.StatusPreconditionFailed && (method == "PUT" || method == "PATCH") {
+			return c.newConflictError(herr, model)
+		}
+		return err
+	}
+	if len(resp.Body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Body, model)
+}
+
+// newConflictError re-fetches the current server state of model via Read and
+// wraps it, along with herr, in a *ConflictError. If the re-fetch itself
+// fails, it returns herr unchanged.
+func (c *Client) newConflictError(herr *HTTPError, model Model) error {
+	current := newModelLike(model)
+	if err := c.Read(model.GetId(), current); err != nil {
+		return herr
+	}
+	return &ConflictError{HTTPError: herr, Current: current}
+}
+
+// newModelLike returns a new, zero-valued Model of the same concrete type as
+// model, suitable as a target for Read.
+func newModelLike(model Model) Model {
+	modelType := reflect.TypeOf(model)
+	if modelType.Kind() == reflect.Ptr {
+		return reflect.New(modelType.Elem()).Interface().(Model)
+	}
+	return reflect.New(modelType).Elem().Interface().(Model)
+}
+
+// doAndUnmarshal sends req with do and unmarshals the response body into v
+// using the json package.
+func (c *Client) doAndUnmarshal(req *http.Request, v interface{}) error {
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	if len(resp.Body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Body, v)
+}
+
+// apiResponse is the header and body of a response that was successfully
+// read, regardless of whether its status code was 2xx.
+type apiResponse struct {
+	Header http.Header
+	Body   []byte
+}
+
+// do sends req with c.HTTPClient, applying UserAgent and Options first. If
+// req's method is idempotent (GET, PUT, or DELETE), it is retried with
+// exponential backoff on network errors and on 5xx or 429 responses,
+// honoring a Retry-After header if the server sent one. It returns the
+// response header and body, or an *HTTPError if the final response has a
+// non-2xx status.
+func (c *Client) do(req *http.Request) (*apiResponse, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't read request body for %s request to %s: %s", req.Method, req.URL.String(), err.Error())
+		}
+		requestBody = b
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	for _, option := range c.Options {
+		if err := option(req); err != nil {
+			return nil, fmt.Errorf("Something went wrong applying a request option to %s request to %s: %s", req.Method, req.URL.String(), err.Error())
+		}
+	}
+	retryable := isIdempotent(req.Method)
+	maxRetries := c.maxRetries()
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if requestBody != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+		}
+		res, err := c.httpClient().Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("Something went wrong with %s request to %s: %s", req.Method, req.URL.String(), err.Error())
+			if retryable && attempt < maxRetries {
+				time.Sleep(retryDelay(attempt, 0))
+				continue
+			}
+			return nil, lastErr
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't read response to %s request to %s: %s", req.Method, req.URL.String(), err.Error())
+		}
+		if retryable && attempt < maxRetries && isRetryableStatus(res.StatusCode) {
+			time.Sleep(retryDelay(attempt, retryAfter(res)))
+			continue
+		}
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return &apiResponse{Header: res.Header, Body: body}, newHTTPError(req, res, body)
+		}
+		return &apiResponse{Header: res.Header, Body: body}, nil
+	}
+	return nil, lastErr
+}
+
+// isIdempotent reports whether method is safe to retry automatically.
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "PUT", "DELETE":
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableStatus reports whether a response with the given status code
+// should be retried: 429 Too Many Requests, or any 5xx.
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// retryDelay returns how long to wait before the next attempt. It honors
+// wait if the server requested a specific delay via Retry-After, and
+// otherwise doubles defaultRetryBackoff for each prior attempt.
+func retryDelay(attempt int, wait time.Duration) time.Duration {
+	if wait > 0 {
+		return wait
+	}
+	return defaultRetryBackoff * time.Duration(1<<uint(attempt))
+}
+
+// retryAfter parses the Retry-After header of res, which per RFC 7231 may be
+// either a number of seconds or an HTTP date. It returns 0 if the header is
+// absent or unparseable.
+func retryAfter(res *http.Response) time.Duration {
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return time.Until(when)
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestClientRetriesOnServerError checks that Read (a GET, which is
+// idempotent) retries after a 500 and succeeds once the server recovers.
+func TestClientRetriesOnServerError(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer srv.Close()
+
+	client := &Client{MaxRetries: 3}
+	todo := &testTodo{Id: "1", Root: srv.URL}
+	if err := client.Read("1", todo); err != nil {
+		t.Fatalf("Read returned an error after recovering: %s", err.Error())
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+// TestClientHonorsRetryAfterSeconds checks that a 429 with a Retry-After
+// header given in seconds delays the next attempt by roughly that long.
+func TestClientHonorsRetryAfterSeconds(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 2 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer srv.Close()
+
+	client := &Client{MaxRetries: 1}
+	todo := &testTodo{Id: "1", Root: srv.URL}
+	start := time.Now()
+	if err := client.Read("1", todo); err != nil {
+		t.Fatalf("Read returned an error after recovering: %s", err.Error())
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Read returned after %s, want at least 1s (the Retry-After delay)", elapsed)
+	}
+}
+
+// TestClientAppliesOptionsOnce checks that a RequestOption which appends a
+// header via Header.Add is only applied once per call to do, even when the
+// request is retried, fixing a bug where it ran once per attempt.
+func TestClientAppliesOptionsOnce(t *testing.T) {
+	attempts := 0
+	var lastAuthHeaders []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		lastAuthHeaders = r.Header.Values("Authorization")
+		if attempts < 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer srv.Close()
+
+	client := &Client{
+		MaxRetries: 1,
+		Options: []RequestOption{
+			func(req *http.Request) error {
+				req.Header.Add("Authorization", "Bearer token")
+				return nil
+			},
+		},
+	}
+	todo := &testTodo{Id: "1", Root: srv.URL}
+	if err := client.Read("1", todo); err != nil {
+		t.Fatalf("Read returned an error after recovering: %s", err.Error())
+	}
+	if len(lastAuthHeaders) != 1 {
+		t.Errorf("Authorization header values = %v, want exactly one value", lastAuthHeaders)
+	}
+}
@@ -0,0 +1,158 @@
+package model
+
+import "sync"
+
+// ChangeType identifies what kind of mutation happened to a Collection.
+type ChangeType int
+
+const (
+	// Added indicates an item was appended to the collection via Create.
+	Added ChangeType = iota
+	// Removed indicates an item was removed from the collection via Delete.
+	Removed
+	// Updated indicates an item already in the collection was changed via
+	// Update, or that the whole collection was replaced via ReadAll (in
+	// which case Index is -1).
+	Updated
+)
+
+// Change describes a single mutation of a Collection, passed to every
+// subscriber registered with Subscribe.
+type Change struct {
+	Type  ChangeType
+	Index int
+}
+
+// Collection wraps a slice of models of type T and emits a Change to every
+// subscriber whenever Create, Update, Delete, or ReadAll is performed
+// through it. view.Bind uses this to re-invoke a View's OnLoad method
+// whenever the underlying data changes, so that UI built on top of a
+// Collection doesn't go stale.
+type Collection[T Model] struct {
+	client *Client
+
+	mu          sync.Mutex
+	items       []T
+	subscribers []func(Change)
+}
+
+// NewCollection returns a new, empty Collection that sends its requests
+// through DefaultClient. Use NewCollectionWithClient to use a different
+// Client.
+func NewCollection[T Model]() *Collection[T] {
+	return NewCollectionWithClient[T](DefaultClient)
+}
+
+// NewCollectionWithClient is like NewCollection, but sends requests through
+// client instead of DefaultClient.
+func NewCollectionWithClient[T Model](client *Client) *Collection[T] {
+	return &Collection[T]{client: client}
+}
+
+// Items returns the collection's current items. The returned slice is owned
+// by the Collection and must not be modified by the caller.
+func (c *Collection[T]) Items() []T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.items
+}
+
+// Subscribe registers fn to be called with a Change every time the
+// collection is mutated through Create, Update, Delete, or ReadAll. It
+// returns a function that unsubscribes fn.
+func (c *Collection[T]) Subscribe(fn func(Change)) (unsubscribe func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscribers = append(c.subscribers, fn)
+	index := len(c.subscribers) - 1
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.subscribers[index] = nil
+	}
+}
+
+func (c *Collection[T]) notify(change Change) {
+	c.mu.Lock()
+	subscribers := append([]func(Change){}, c.subscribers...)
+	c.mu.Unlock()
+	for _, fn := range subscribers {
+		if fn != nil {
+			fn(change)
+		}
+	}
+}
+
+// Create sends item to the server with c's Client and, on success, appends
+// it to the collection and notifies subscribers with an Added Change.
+func (c *Collection[T]) Create(item T) error {
+	if err := c.client.Create(item); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.items = append(c.items, item)
+	index := len(c.items) - 1
+	c.mu.Unlock()
+	c.notify(Change{Type: Added, Index: index})
+	return nil
+}
+
+// Update sends item to the server with c's Client and, on success, replaces
+// the matching item in the collection (matched by GetId) and notifies
+// subscribers with an Updated Change.
+func (c *Collection[T]) Update(item T) error {
+	if err := c.client.Update(item); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	index := c.indexOf(item.GetId())
+	if index >= 0 {
+		c.items[index] = item
+	}
+	c.mu.Unlock()
+	c.notify(Change{Type: Updated, Index: index})
+	return nil
+}
+
+// Delete sends item to the server with c's Client and, on success, removes
+// the matching item from the collection (matched by GetId) and notifies
+// subscribers with a Removed Change.
+func (c *Collection[T]) Delete(item T) error {
+	if err := c.client.Delete(item); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	index := c.indexOf(item.GetId())
+	if index >= 0 {
+		c.items = append(c.items[:index], c.items[index+1:]...)
+	}
+	c.mu.Unlock()
+	c.notify(Change{Type: Removed, Index: index})
+	return nil
+}
+
+// ReadAll replaces the collection's items by fetching them from the server
+// with c's Client, and notifies subscribers with an Updated Change whose
+// Index is -1, since every item may have changed.
+func (c *Collection[T]) ReadAll() error {
+	var items []T
+	if err := c.client.ReadAll(&items); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.items = items
+	c.mu.Unlock()
+	c.notify(Change{Type: Updated, Index: -1})
+	return nil
+}
+
+// indexOf returns the index of the item whose GetId matches id, or -1 if
+// there is none. The caller must hold c.mu.
+func (c *Collection[T]) indexOf(id string) int {
+	for i, item := range c.items {
+		if item.GetId() == id {
+			return i
+		}
+	}
+	return -1
+}
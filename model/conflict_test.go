@@ -0,0 +1,72 @@
+package model
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testETagTodoRoot is the RootURL every testETagTodo points at. The conflict
+// path re-fetches with a freshly instantiated zero-value model (see
+// newModelLike), so unlike testTodo, RootURL can't depend on instance state
+// here.
+var testETagTodoRoot string
+
+type testETagTodo struct {
+	Id          string `json:"id"`
+	Title       string `json:"title"`
+	IsCompleted bool   `json:"isCompleted"`
+	Tag         string `json:"-"`
+}
+
+func (t *testETagTodo) GetId() string      { return t.Id }
+func (t *testETagTodo) RootURL() string    { return testETagTodoRoot }
+func (t *testETagTodo) ETag() string       { return t.Tag }
+func (t *testETagTodo) SetETag(tag string) { t.Tag = tag }
+
+// TestPatchConflictRefetchesCurrent checks that when a PATCH is rejected with
+// 412 Precondition Failed because the model's ETag is stale, Patch returns a
+// *ConflictError whose Current field holds the server's latest state,
+// re-fetched with Read.
+func TestPatchConflictRefetchesCurrent(t *testing.T) {
+	current := `{"id":"1","title":"buy milk","isCompleted":true}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "PATCH":
+			w.WriteHeader(http.StatusPreconditionFailed)
+		case "GET":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", `"v2"`)
+			fmt.Fprint(w, current)
+		default:
+			t.Fatalf("unexpected method %s", r.Method)
+		}
+	}))
+	defer srv.Close()
+	testETagTodoRoot = srv.URL
+
+	client := &Client{}
+	todo := &testETagTodo{Id: "1", Title: "buy milk", Tag: `"v1"`}
+	err := client.Patch(todo, "IsCompleted")
+	if err == nil {
+		t.Fatal("expected a *ConflictError, got nil")
+	}
+	conflict, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("expected *ConflictError, got %T: %s", err, err.Error())
+	}
+	if conflict.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("conflict.StatusCode = %d, want %d", conflict.StatusCode, http.StatusPreconditionFailed)
+	}
+	currentTodo, ok := conflict.Current.(*testETagTodo)
+	if !ok {
+		t.Fatalf("expected conflict.Current to be a *testETagTodo, got %T", conflict.Current)
+	}
+	if !currentTodo.IsCompleted {
+		t.Error("conflict.Current.IsCompleted = false, want true (the server's latest state)")
+	}
+	if currentTodo.Tag != `"v2"` {
+		t.Errorf("conflict.Current's ETag = %q, want %q", currentTodo.Tag, `"v2"`)
+	}
+}
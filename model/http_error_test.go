@@ -0,0 +1,88 @@
+package model
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testTodo struct {
+	Id    string `json:"id"`
+	Title string `json:"title"`
+	Root  string `json:"-"`
+}
+
+func (t *testTodo) GetId() string   { return t.Id }
+func (t *testTodo) RootURL() string { return t.Root }
+
+// TestHTTPErrorDecodesProblemJSON checks that a non-2xx response with a
+// Content-Type of application/problem+json is decoded into HTTPError.Problem
+// per RFC 7807.
+func TestHTTPErrorDecodesProblemJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"type":"about:blank","title":"Bad Request","status":400,"detail":"title is required","instance":"/todos/1"}`))
+	}))
+	defer srv.Close()
+
+	client := &Client{}
+	todo := &testTodo{Id: "1", Root: srv.URL}
+	err := client.Create(todo)
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	herr, ok := err.(*HTTPError)
+	if !ok {
+		t.Fatalf("expected *HTTPError, got %T: %s", err, err.Error())
+	}
+	if herr.Problem == nil {
+		t.Fatal("expected herr.Problem to be populated, got nil")
+	}
+	if herr.Problem.Detail != "title is required" {
+		t.Errorf("herr.Problem.Detail = %q, want %q", herr.Problem.Detail, "title is required")
+	}
+}
+
+// TestCreateSkipsUnmarshalOnEmptyBody checks that a 201 response with an
+// empty body does not produce a json.Unmarshal error.
+func TestCreateSkipsUnmarshalOnEmptyBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client := &Client{}
+	todo := &testTodo{Id: "1", Root: srv.URL}
+	if err := client.Create(todo); err != nil {
+		t.Fatalf("Create returned an error for an empty 201 body: %s", err.Error())
+	}
+}
+
+// testTodoListRoot is the RootURL every testTodoList points at. ReadAll
+// discovers the root by instantiating a zero-value element of the slice, so
+// unlike testTodo, RootURL can't depend on instance state here.
+var testTodoListRoot string
+
+type testTodoList struct {
+	Id string `json:"id"`
+}
+
+func (t *testTodoList) GetId() string   { return t.Id }
+func (t *testTodoList) RootURL() string { return testTodoListRoot }
+
+// TestReadAllSkipsUnmarshalOnNoContent checks that a 204 No Content response
+// to ReadAll does not produce a json.Unmarshal error.
+func TestReadAllSkipsUnmarshalOnNoContent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	testTodoListRoot = srv.URL
+
+	client := &Client{}
+	var todos []*testTodoList
+	if err := client.ReadAll(&todos); err != nil {
+		t.Fatalf("ReadAll returned an error for a 204 response: %s", err.Error())
+	}
+}
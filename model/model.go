@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -16,28 +16,130 @@ type Model interface {
 	RootURL() string
 }
 
+// URLTemplater is implemented by models whose request path is not simply
+// RootURL() or RootURL()+"/"+GetId(). URLTemplate should return a path
+// template with "{name}" placeholders, where each name matches the `url`
+// struct tag of one of the model's fields, e.g. "/products/{product}/reviews".
+// If a model implements URLTemplater, its return value is used in place of
+// RootURL() when building requests for Create and Update.
+type URLTemplater interface {
+	URLTemplate() string
+}
+
+// FormEncoder is implemented by models that want to opt out of the default
+// JSON request body and fall back to the original
+// application/x-www-form-urlencoded encoding. RequestEncoding should return
+// formEncoding.
+type FormEncoder interface {
+	RequestEncoding() string
+}
+
+// formEncoding is the value a Model's RequestEncoding method should return
+// to opt in to application/x-www-form-urlencoded request bodies.
+const formEncoding = "form"
+
+// Problem is an RFC 7807 "problem+json" body, which servers may use to
+// describe an error in more detail than a bare status code. It is attached
+// to an HTTPError whenever the response Content-Type is
+// application/problem+json.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+}
+
+// HTTPError is returned by Create, Read, ReadAll, Update, and Delete whenever
+// the server responds with a non-2xx status code. It captures enough of the
+// response for callers to decide how to handle the failure instead of
+// receiving a confusing json.Unmarshal error. If the response Content-Type is
+// application/problem+json, Problem is populated with the decoded body per
+// RFC 7807.
+type HTTPError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Header     http.Header
+	Body       []byte
+	Problem    *Problem
+}
+
+func (e *HTTPError) Error() string {
+	if e.Problem != nil && e.Problem.Detail != "" {
+		return fmt.Sprintf("%s %s: server responded with status %d: %s", e.Method, e.URL, e.StatusCode, e.Problem.Detail)
+	}
+	return fmt.Sprintf("%s %s: server responded with status %d", e.Method, e.URL, e.StatusCode)
+}
+
+// newHTTPError builds an HTTPError from a request, its response, and the
+// already-read response body, decoding body as a Problem if res declares a
+// Content-Type of application/problem+json.
+func newHTTPError(req *http.Request, res *http.Response, body []byte) *HTTPError {
+	herr := &HTTPError{
+		StatusCode: res.StatusCode,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Header:     res.Header,
+		Body:       body,
+	}
+	if mediaType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type")); err == nil && mediaType == "application/problem+json" {
+		problem := &Problem{}
+		if err := json.Unmarshal(body, problem); err == nil {
+			herr.Problem = problem
+		}
+	}
+	return herr
+}
+
+// ETager is implemented by models that track a server-assigned ETag for
+// optimistic concurrency. If a model implements ETager, Update and Patch send
+// its ETag() as the If-Match header, so the server can reject the request
+// with 412 Precondition Failed if the resource has changed since the ETag
+// was issued.
+type ETager interface {
+	ETag() string
+}
+
+// ETagSetter is implemented by models that want Read to record the ETag the
+// server returned, so that a later Update or Patch can send it back via
+// ETager and detect concurrent changes.
+type ETagSetter interface {
+	SetETag(string)
+}
+
+// ConflictError is returned by Update and Patch when the server responds
+// with 412 Precondition Failed because the model's ETag no longer matches
+// the stored resource. Current holds the server's current state of the
+// resource, re-fetched with Read, so callers can decide how to merge their
+// change or retry.
+type ConflictError struct {
+	*HTTPError
+	Current Model
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: resource was changed concurrently", e.HTTPError.Error())
+}
+
 // Create expects a pointer some concrete type which implements Model (e.g., *Todo).
 // It will send a POST request to the RESTful server. It expects a JSON containing the
 // created object from the server if the request was successful, and will set the fields of
 // model with the data in the response object. It will use the RootURL() method of
-// the model to determine which url to send the POST request to.
+// the model to determine which url to send the POST request to, unless model implements
+// URLTemplater, in which case URLTemplate() is used instead. Create sends the request
+// with DefaultClient.
 func Create(model Model) error {
-	fullURL := model.RootURL()
-	encodedModelData, err := encodeModelFields(model)
-	if err != nil {
-		return err
-	}
-	return sendRequestAndUnmarshal("POST", fullURL, encodedModelData, model)
+	return DefaultClient.Create(model)
 }
 
 // Read will send a GET request to a RESTful server to get the model by the given id,
 // then it will scan the results into model. It expects a json object which contains all
 // the fields for the requested model. Read will use the RootURL() method of the model to
 // figure out which url to send the GET request to. Typically the full url will look something
-// like "http://hostname.com/todos/123"
+// like "http://hostname.com/todos/123". Read sends the request with DefaultClient.
 func Read(id string, model Model) error {
-	fullURL := model.RootURL() + "/" + id
-	return sendRequestAndUnmarshal("GET", fullURL, "", model)
+	return DefaultClient.Read(id, model)
 }
 
 // ReadAll expects a pointer to a slice of poitners to some concrete type
@@ -45,45 +147,50 @@ func Read(id string, model Model) error {
 // a RESTful server and scan the results into models. It expects a json array
 // of json objects from the server, where each object represents a single Model
 // of some concrete type. It will use the RootURL() method of the models to
-// figure out which url to send the GET request to.
+// figure out which url to send the GET request to. ReadAll sends the request
+// with DefaultClient.
 func ReadAll(models interface{}) error {
-	rootURL, err := getURLFromModels(models)
-	if err != nil {
-		return err
-	}
-	return sendRequestAndUnmarshal("GET", rootURL, "", models)
+	return DefaultClient.ReadAll(models)
 }
 
 // Update expects a pointer some concrete type which implements Model (e.g., *Todo), with a model.Id
 // that matches a stored object on the server. It will send a PUT request to the RESTful server.
 // It expects a JSON containing the updated object from the server if the request was successful,
 // and will set the fields of model with the data in the response object.
-// It will use the RootURL() method of the model to determine which url to send the PUT request to.
+// It will use the RootURL() method of the model to determine which url to send the PUT request to,
+// unless model implements URLTemplater, in which case URLTemplate() is used instead. Update sends
+// the request with DefaultClient.
 func Update(model Model) error {
-	fullURL := model.RootURL() + "/" + model.GetId()
-	encodedModelData, err := encodeModelFields(model)
-	if err != nil {
-		return err
-	}
-	return sendRequestAndUnmarshal("PUT", fullURL, encodedModelData, model)
+	return DefaultClient.Update(model)
+}
+
+// Patch expects a pointer some concrete type which implements Model (e.g., *Todo),
+// with a model.Id that matches a stored object on the server, and the names of the
+// fields that changed. Unlike Update, it sends only those fields, as a JSON Merge
+// Patch (RFC 7396) body with Content-Type application/merge-patch+json, so it is
+// safe to use when other clients may be concurrently editing different fields of
+// the same resource. Patch sends the request with DefaultClient.
+func Patch(model Model, fields ...string) error {
+	return DefaultClient.Patch(model, fields...)
 }
 
 // Delete expects a pointer some concrete type which implements Model (e.g., *Todo).
-// It will send a DELETE request to a RESTful server. It expects an empty json
-// object from the server if the request was successful, and will not attempt to do anything
-// with the response. It will use the RootURL() and GetId() methods of the model to determine
-// which url to send the DELETE request to. Typically, the full url will look something
-// like "http://hostname.com/todos/123"
+// It will send a DELETE request to a RESTful server. It will use the RootURL() and
+// GetId() methods of the model to determine which url to send the DELETE request to.
+// Typically, the full url will look something like "http://hostname.com/todos/123".
+// If the server responds with a non-2xx status code, Delete returns an *HTTPError.
+// Delete sends the request with DefaultClient.
 func Delete(model Model) error {
-	fullURL := model.RootURL() + "/" + model.GetId()
-	req, err := http.NewRequest("DELETE", fullURL, nil)
-	if err != nil {
-		return fmt.Errorf("Something went wrong building DELETE request to %s: %s", fullURL, err.Error())
-	}
-	if _, err := http.DefaultClient.Do(req); err != nil {
-		return fmt.Errorf("Something went wrong with DELETE request to %s: %s", fullURL, err.Error())
+	return DefaultClient.Delete(model)
+}
+
+// baseURLFor returns the url that should be used to build requests for model,
+// preferring URLTemplate() over RootURL() when model implements URLTemplater.
+func baseURLFor(model Model) string {
+	if t, ok := model.(URLTemplater); ok {
+		return t.URLTemplate()
 	}
-	return nil
+	return model.RootURL()
 }
 
 // getURLFromModels returns the url that should be used for the type that corresponds
@@ -132,59 +239,189 @@ func getURLFromModels(models interface{}) (string, error) {
 	return newModel.RootURL(), nil
 }
 
-// sendRequestAndUnmarshal constructs a request with the given method, url, and
-// data. If data is an empty string, it will construct a request without any
-// data in the body. If data is a non-empty string, it will send it as the body
-// of the request and set the Content-Type header to
-// application/x-www-form-urlencoded. Then sendRequestAndUnmarshal sends the
-// request using http.DefaultClient and marshals the response into v using the json
-// package.
-// TODO: do something if the response status code is non-200.
-func sendRequestAndUnmarshal(method string, url string, data string, v interface{}) error {
-	// Build the request
-	req, err := http.NewRequest(method, url, strings.NewReader(data))
-	if err != nil {
-		return fmt.Errorf("Something went wrong building %s request to %s: %s", method, url, err.Error())
-	}
-	// Set the Content-Type header only if data was provided
-	if data != "" {
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	}
-	// Send the request using the default client
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("Something went wrong with %s request to %s: %s", req.Method, req.URL.String(), err.Error())
-	}
-	// Unmarshal the response into v
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return fmt.Errorf("Couldn't read response to %s: %s", res.Request.URL.String(), err.Error())
-	}
-	return json.Unmarshal(body, v)
-}
-
-// encodeModelFields returns the fields of model represented as a url-encoded string.
-// Suitable for POST requests with a content type of application/x-www-form-urlencoded.
-// It returns an error if model is a nil pointer or if it is not a struct or a pointer
-// to a struct. Any fields that are nil will not be added to the url-encoded string.
-func encodeModelFields(model Model) (string, error) {
+// encodedRequest holds the pieces of a request that were derived from a
+// Model's struct tags: the body and its Content-Type, values to lift into
+// the query string, headers, cookies, and values to substitute into a
+// URLTemplate.
+type encodedRequest struct {
+	body        []byte
+	contentType string
+	query       url.Values
+	header      http.Header
+	cookies     []*http.Cookie
+	urlValues   map[string]string
+}
+
+// buildURL substitutes enc.urlValues into base (replacing each "{name}"
+// placeholder) and appends enc.query as a query string.
+func (enc *encodedRequest) buildURL(base string) string {
+	fullURL := base
+	for name, value := range enc.urlValues {
+		fullURL = strings.Replace(fullURL, "{"+name+"}", url.PathEscape(value), -1)
+	}
+	if len(enc.query) > 0 {
+		separator := "?"
+		if strings.Contains(fullURL, "?") {
+			separator = "&"
+		}
+		fullURL += separator + enc.query.Encode()
+	}
+	return fullURL
+}
+
+// encodeModelFields inspects the struct tags of model's fields and routes
+// each field according to its tag: `query` fields are lifted into the url
+// query string, `header` fields become request headers, `cookie` fields
+// become cookies, and `url` fields are substituted into a URLTemplate. Any
+// field without one of those tags is included in the JSON request body,
+// using its `json` tag (name and omitempty) the same way encoding/json
+// would. If one or more onlyFields are given, every other field is skipped
+// entirely regardless of its tag, which Patch uses to send just the fields
+// that changed. It returns an error if model is a nil pointer or if it is
+// not a struct or a pointer to a struct.
+func encodeModelFields(model Model, onlyFields ...string) (*encodedRequest, error) {
+	modelVal := reflect.ValueOf(model)
+	for modelVal.Kind() == reflect.Ptr {
+		if modelVal.IsNil() {
+			return nil, errors.New("Error encoding model: model was a nil pointer.")
+		}
+		modelVal = modelVal.Elem()
+	}
+	if modelVal.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Error encoding model: model must be a struct or a pointer to a struct.")
+	}
+	var only map[string]bool
+	if len(onlyFields) > 0 {
+		only = make(map[string]bool, len(onlyFields))
+		for _, name := range onlyFields {
+			only[name] = true
+		}
+	}
+	enc := &encodedRequest{
+		query:     url.Values{},
+		header:    http.Header{},
+		urlValues: map[string]string{},
+	}
+	jsonFields := map[string]interface{}{}
+	modelType := modelVal.Type()
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if field.PkgPath != "" {
+			// Unexported field; skip it the way encoding/json does, since
+			// reflect can't read its value.
+			continue
+		}
+		if only != nil && !only[field.Name] {
+			continue
+		}
+		fieldValue := modelVal.Field(i)
+		for fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				fieldValue = reflect.Value{}
+				break
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		if !fieldValue.IsValid() {
+			// nil pointer field, omit it entirely regardless of destination.
+			continue
+		}
+		switch {
+		case field.Tag.Get("url") != "":
+			enc.urlValues[field.Tag.Get("url")] = fmt.Sprint(fieldValue.Interface())
+		case field.Tag.Get("query") != "":
+			enc.query.Set(field.Tag.Get("query"), fmt.Sprint(fieldValue.Interface()))
+		case field.Tag.Get("header") != "":
+			enc.header.Set(field.Tag.Get("header"), fmt.Sprint(fieldValue.Interface()))
+		case field.Tag.Get("cookie") != "":
+			enc.cookies = append(enc.cookies, &http.Cookie{
+				Name:  field.Tag.Get("cookie"),
+				Value: fmt.Sprint(fieldValue.Interface()),
+			})
+		default:
+			name, omitempty := jsonFieldTag(field)
+			if name == "-" {
+				continue
+			}
+			if omitempty && isEmptyValue(fieldValue) {
+				continue
+			}
+			jsonFields[name] = fieldValue.Interface()
+		}
+	}
+	if len(jsonFields) > 0 {
+		body, err := json.Marshal(jsonFields)
+		if err != nil {
+			return nil, fmt.Errorf("Error encoding model as JSON: %s", err.Error())
+		}
+		enc.body = body
+		enc.contentType = "application/json"
+	}
+	return enc, nil
+}
+
+// jsonFieldTag parses field's `json` struct tag the way encoding/json does,
+// returning the effective field name (falling back to field.Name) and
+// whether the omitempty option was set.
+func jsonFieldTag(field reflect.StructField) (name string, omitempty bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty
+}
+
+// isEmptyValue reports whether v is the zero value for its type, mirroring
+// the definition encoding/json uses to implement the omitempty tag option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	}
+	return false
+}
+
+// encodeModelFieldsForm returns the fields of model represented as a
+// url-encoded request body, for models that opt in via FormEncoder. It
+// returns an error if model is a nil pointer or if it is not a struct or a
+// pointer to a struct. Any fields that are nil will not be added to the
+// url-encoded string.
+func encodeModelFieldsForm(model Model) (*encodedRequest, error) {
 	modelVal := reflect.ValueOf(model)
 	// dereference the pointer until we reach the underlying struct value.
 	for modelVal.Kind() == reflect.Ptr {
 		if modelVal.IsNil() {
-			return "", errors.New("Error encoding model as url-encoded data: model was a nil pointer.")
+			return nil, errors.New("Error encoding model as url-encoded data: model was a nil pointer.")
 		}
 		modelVal = modelVal.Elem()
 	}
 	// Make sure the type of model after dereferencing is a struct.
 	if modelVal.Kind() != reflect.Struct {
-		return "", fmt.Errorf("Error encoding model as url-encoded data: model must be a struct or a pointer to a struct.")
+		return nil, fmt.Errorf("Error encoding model as url-encoded data: model must be a struct or a pointer to a struct.")
 	}
 	encodedFields := []string{}
 	for i := 0; i < modelVal.Type().NumField(); i++ {
 		field := modelVal.Type().Field(i)
 		fieldValue := modelVal.FieldByName(field.Name)
-		encodedField, err := encodeField(field, fieldValue)
+		encodedField, err := encodeFormField(field, fieldValue)
 		if err != nil {
 			if _, ok := err.(nilFieldError); ok {
 				// If there was a nil field, continue without adding the field
@@ -192,11 +429,17 @@ func encodeModelFields(model Model) (string, error) {
 				continue
 			}
 			// We should return any other kind of error
-			return "", err
+			return nil, err
 		}
 		encodedFields = append(encodedFields, field.Name+"="+encodedField)
 	}
-	return strings.Join(encodedFields, "&"), nil
+	return &encodedRequest{
+		body:        []byte(strings.Join(encodedFields, "&")),
+		contentType: "application/x-www-form-urlencoded",
+		query:       url.Values{},
+		header:      http.Header{},
+		urlValues:   map[string]string{},
+	}, nil
 }
 
 type nilFieldError struct{}
@@ -205,11 +448,11 @@ func (nilFieldError) Error() string {
 	return "field was nil"
 }
 
-// encodeField converts a field with the given value to a string. It returns an error
+// encodeFormField converts a field with the given value to a string. It returns an error
 // if field has a type which is unsupported. It returns a special error (nilFieldError)
 // if a field has a value of nil. The supported types are int and its variants (int64,
 // int32, etc.), uint and its variants (uint64, uint32, etc.), bool, string, and []byte.
-func encodeField(field reflect.StructField, value reflect.Value) (string, error) {
+func encodeFormField(field reflect.StructField, value reflect.Value) (string, error) {
 	for value.Kind() == reflect.Ptr {
 		if value.IsNil() {
 			// Skip nil fields
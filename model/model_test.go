@@ -0,0 +1,115 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+type testEncodingModel struct {
+	Id        string `humble:"id"`
+	Title     string `json:"title"`
+	Secret    string `json:"-"`
+	Empty     string `json:"empty,omitempty"`
+	APIKey    string `query:"api_key"`
+	Requester string `header:"X-Requester"`
+	Session   string `cookie:"session"`
+	Product   string `url:"product"`
+}
+
+func (m *testEncodingModel) GetId() string   { return m.Id }
+func (m *testEncodingModel) RootURL() string { return "/products/{product}/reviews" }
+
+// TestEncodeModelFieldsRoutesByTag checks that encodeModelFields routes each
+// field to the right part of the request according to its struct tag:
+// `query`, `header`, `cookie`, and `url` fields are lifted out of the JSON
+// body, `json:"-"` is skipped entirely, and `omitempty` empty fields are
+// dropped from the body.
+func TestEncodeModelFieldsRoutesByTag(t *testing.T) {
+	m := &testEncodingModel{
+		Id:        "1",
+		Title:     "hello",
+		Secret:    "shh",
+		APIKey:    "key123",
+		Requester: "alice",
+		Session:   "sess456",
+		Product:   "widget",
+	}
+	enc, err := encodeModelFields(m)
+	if err != nil {
+		t.Fatalf("encodeModelFields returned an error: %s", err.Error())
+	}
+	if got := enc.query.Get("api_key"); got != "key123" {
+		t.Errorf("query[api_key] = %q, want %q", got, "key123")
+	}
+	if got := enc.header.Get("X-Requester"); got != "alice" {
+		t.Errorf("header[X-Requester] = %q, want %q", got, "alice")
+	}
+	if len(enc.cookies) != 1 || enc.cookies[0].Value != "sess456" {
+		t.Errorf("cookies = %v, want one cookie with value %q", enc.cookies, "sess456")
+	}
+	if got := enc.urlValues["product"]; got != "widget" {
+		t.Errorf("urlValues[product] = %q, want %q", got, "widget")
+	}
+	fullURL := enc.buildURL(m.RootURL())
+	if want := "/products/widget/reviews?api_key=key123"; fullURL != want {
+		t.Errorf("buildURL = %q, want %q", fullURL, want)
+	}
+	body := string(enc.body)
+	if !strings.Contains(body, `"title":"hello"`) {
+		t.Errorf("body %s doesn't contain the title field", body)
+	}
+	if strings.Contains(body, "shh") {
+		t.Errorf(`body %s contains the json:"-" Secret field`, body)
+	}
+	if strings.Contains(body, "empty") {
+		t.Errorf("body %s contains the omitempty Empty field despite being empty", body)
+	}
+	if strings.Contains(body, "api_key") || strings.Contains(body, "X-Requester") || strings.Contains(body, "session") || strings.Contains(body, "product") {
+		t.Errorf("body %s contains a field that should have been routed elsewhere", body)
+	}
+}
+
+type testModelWithUnexportedField struct {
+	Id     string `humble:"id"`
+	Title  string `json:"title"`
+	secret string
+}
+
+func (m *testModelWithUnexportedField) GetId() string   { return m.Id }
+func (m *testModelWithUnexportedField) RootURL() string { return "/widgets" }
+
+// TestEncodeModelFieldsSkipsUnexportedFields checks that an unexported,
+// untagged field is skipped rather than panicking when reflect tries to read
+// its value, the same way encoding/json treats unexported fields.
+func TestEncodeModelFieldsSkipsUnexportedFields(t *testing.T) {
+	m := &testModelWithUnexportedField{Id: "1", Title: "hello", secret: "shh"}
+	enc, err := encodeModelFields(m)
+	if err != nil {
+		t.Fatalf("encodeModelFields returned an error: %s", err.Error())
+	}
+	body := string(enc.body)
+	if !strings.Contains(body, `"title":"hello"`) {
+		t.Errorf("body %s doesn't contain the title field", body)
+	}
+	if strings.Contains(body, "shh") {
+		t.Errorf("body %s contains the unexported secret field", body)
+	}
+}
+
+// TestEncodeModelFieldsOnlyFields checks that passing onlyFields restricts
+// the body to just those fields, which Patch relies on to send a partial
+// update.
+func TestEncodeModelFieldsOnlyFields(t *testing.T) {
+	m := &testEncodingModel{Id: "1", Title: "hello", APIKey: "key123"}
+	enc, err := encodeModelFields(m, "Title")
+	if err != nil {
+		t.Fatalf("encodeModelFields returned an error: %s", err.Error())
+	}
+	if got := enc.query.Get("api_key"); got != "" {
+		t.Errorf("query[api_key] = %q, want empty since APIKey wasn't in onlyFields", got)
+	}
+	body := string(enc.body)
+	if !strings.Contains(body, `"title":"hello"`) {
+		t.Errorf("body %s doesn't contain the title field", body)
+	}
+}
@@ -0,0 +1,34 @@
+// Package view provides Bind, which keeps a View current as the
+// model.Collection backing it changes. View and the rest of the rendering
+// machinery (Update, QuerySelectorAll, and friends) are part of the
+// existing humble/view package, not redeclared here.
+package view
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gophergala/humble/model"
+)
+
+// ErrorHandler is called with any error that Update returns when a Bind
+// subscription fires. The default implementation writes to stderr; assign a
+// different function to handle failures another way.
+var ErrorHandler = func(err error) {
+	fmt.Fprintln(os.Stderr, "view: "+err.Error())
+}
+
+// Bind subscribes v to c, so that v is fully re-rendered via Update (which
+// re-invokes RenderHTML and OnLoad) every time c is mutated through Create,
+// Update, Delete, or ReadAll. This lets a View built on top of a Collection
+// stay current without every call site that mutates c needing to know which
+// Views are showing its data. Any error Update returns is passed to
+// ErrorHandler rather than discarded. Bind returns a function that removes
+// the binding.
+func Bind[T model.Model](v View, c *model.Collection[T]) (unbind func()) {
+	return c.Subscribe(func(model.Change) {
+		if err := Update(v); err != nil {
+			ErrorHandler(err)
+		}
+	})
+}